@@ -0,0 +1,298 @@
+package doc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// FieldAccessor exposes typed, path-based access to the fields of a parsed
+// document, modeled after airshipctl's document API. Paths use the same
+// ":"-delimited syntax as doc.Identifiers for map keys (e.g. "spec:replicas"),
+// with an additional "[i]" suffix to index into arrays (e.g.
+// "spec:template:spec:containers[0]:image"). Note that doc.Identifiers
+// itself never contains "[i]" suffixes today — createFlatStructure walks
+// array elements without recording their index — so an identifier can't yet
+// be fed back into GetFieldValue verbatim to reach a value inside an array;
+// indexed paths have to be constructed by the caller.
+type FieldAccessor interface {
+	GetFieldValue(path string) (interface{}, error)
+	GetString(path string) (string, error)
+	GetInt64(path string) (int64, error)
+	GetFloat64(path string) (float64, error)
+	GetBool(path string) (bool, error)
+	GetMap(path string) (map[string]interface{}, error)
+	GetSlice(path string) ([]interface{}, error)
+	GetStringMap(path string) (map[string]string, error)
+	GetStringSlice(path string) ([]string, error)
+	GetKind() (string, error)
+	GetName() (string, error)
+	GetNamespace() (string, error)
+	GetGroup() (string, error)
+	GetLabels() (map[string]string, error)
+	GetAnnotations() (map[string]string, error)
+	AsYAML() ([]byte, error)
+}
+
+var _ FieldAccessor = (*KustomizationDocument)(nil)
+
+// pathSegmentRegexp splits a single ":"-delimited path segment into its key
+// and any trailing "[i]" array indices, e.g. "containers[0]" -> "containers", [0].
+var pathSegmentRegexp = regexp.MustCompile(`^([^\[\]]*)((?:\[\d+\])*)$`)
+var pathIndexRegexp = regexp.MustCompile(`\[(\d+)\]`)
+
+type pathSegment struct {
+	key     string
+	indices []int
+}
+
+func parsePath(path string) ([]pathSegment, error) {
+	path = strings.TrimLeft(path, ":")
+	if path == "" {
+		return nil, nil
+	}
+	parts := strings.Split(path, ":")
+	segments := make([]pathSegment, 0, len(parts))
+	for _, part := range parts {
+		m := pathSegmentRegexp.FindStringSubmatch(part)
+		if m == nil || m[1] == "" {
+			return nil, fmt.Errorf("invalid path segment %q in %q", part, path)
+		}
+		seg := pathSegment{key: m[1]}
+		for _, idx := range pathIndexRegexp.FindAllStringSubmatch(m[2], -1) {
+			i, err := strconv.Atoi(idx[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index in %q: %v", part, err)
+			}
+			seg.indices = append(seg.indices, i)
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// GetFieldValue resolves path against the document's parsed contents and
+// returns the raw value found there, or an error if no such field exists.
+func (doc *KustomizationDocument) GetFieldValue(path string) (interface{}, error) {
+	contents, err := doc.rootContents()
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cur interface{} = contents
+	for _, seg := range segments {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot index %q: not a map at %q", path, seg.key)
+		}
+		v, ok := m[seg.key]
+		if !ok {
+			return nil, fmt.Errorf("field %q not found in %q", seg.key, path)
+		}
+		cur = v
+		for _, idx := range seg.indices {
+			s, ok := cur.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot index %q[%d]: not a slice", seg.key, idx)
+			}
+			if idx < 0 || idx >= len(s) {
+				return nil, fmt.Errorf("index %d out of range for %q (len %d)", idx, seg.key, len(s))
+			}
+			cur = s[idx]
+		}
+	}
+	return cur, nil
+}
+
+// rootContents returns the first parsed document, which for a kustomization
+// file is its sole top-level map.
+func (doc *KustomizationDocument) rootContents() (map[string]interface{}, error) {
+	ks, err := doc.parsedContents()
+	if err != nil {
+		return nil, err
+	}
+	if len(ks) == 0 {
+		return nil, fmt.Errorf("document has no parsed contents")
+	}
+	return ks[0], nil
+}
+
+func (doc *KustomizationDocument) GetString(path string) (string, error) {
+	v, err := doc.GetFieldValue(path)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("value at %q is not a string: %v", path, v)
+	}
+	return s, nil
+}
+
+func (doc *KustomizationDocument) GetInt64(path string) (int64, error) {
+	v, err := doc.GetFieldValue(path)
+	if err != nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case float64:
+		return int64(n), nil
+	default:
+		return 0, fmt.Errorf("value at %q is not an int64: %v", path, v)
+	}
+}
+
+func (doc *KustomizationDocument) GetFloat64(path string) (float64, error) {
+	v, err := doc.GetFieldValue(path)
+	if err != nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int64:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	default:
+		return 0, fmt.Errorf("value at %q is not a float64: %v", path, v)
+	}
+}
+
+func (doc *KustomizationDocument) GetBool(path string) (bool, error) {
+	v, err := doc.GetFieldValue(path)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("value at %q is not a bool: %v", path, v)
+	}
+	return b, nil
+}
+
+func (doc *KustomizationDocument) GetMap(path string) (map[string]interface{}, error) {
+	v, err := doc.GetFieldValue(path)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value at %q is not a map: %v", path, v)
+	}
+	return m, nil
+}
+
+func (doc *KustomizationDocument) GetSlice(path string) ([]interface{}, error) {
+	v, err := doc.GetFieldValue(path)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value at %q is not a slice: %v", path, v)
+	}
+	return s, nil
+}
+
+func (doc *KustomizationDocument) GetStringMap(path string) (map[string]string, error) {
+	m, err := doc.GetMap(path)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("value for key %q at %q is not a string: %v", k, path, v)
+		}
+		out[k] = s
+	}
+	return out, nil
+}
+
+func (doc *KustomizationDocument) GetStringSlice(path string) ([]string, error) {
+	s, err := doc.GetSlice(path)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(s))
+	for _, v := range s {
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("element of %q is not a string: %v", path, v)
+		}
+		out = append(out, str)
+	}
+	return out, nil
+}
+
+// GetKind returns the document's kind, defaulting to "Kustomization" when
+// the field is absent or not a non-empty string, matching the getKind
+// logic in ParseYAML.
+func (doc *KustomizationDocument) GetKind() (string, error) {
+	if _, err := doc.rootContents(); err != nil {
+		return "", err
+	}
+	v, err := doc.GetFieldValue("kind")
+	if err != nil {
+		// Field genuinely absent: default, same as ParseYAML's getKind.
+		return "Kustomization", nil
+	}
+	kind, ok := v.(string)
+	if !ok || kind == "" {
+		return "Kustomization", nil
+	}
+	return kind, nil
+}
+
+func (doc *KustomizationDocument) GetName() (string, error) {
+	return doc.GetString("metadata:name")
+}
+
+func (doc *KustomizationDocument) GetNamespace() (string, error) {
+	return doc.GetString("metadata:namespace")
+}
+
+// GetGroup returns the API group portion of apiVersion, e.g. "apps" for
+// "apps/v1", or "" for core-group resources like "v1".
+func (doc *KustomizationDocument) GetGroup() (string, error) {
+	apiVersion, err := doc.GetString("apiVersion")
+	if err != nil {
+		return "", err
+	}
+	if idx := strings.Index(apiVersion, "/"); idx >= 0 {
+		return apiVersion[:idx], nil
+	}
+	return "", nil
+}
+
+func (doc *KustomizationDocument) GetLabels() (map[string]string, error) {
+	return doc.GetStringMap("metadata:labels")
+}
+
+func (doc *KustomizationDocument) GetAnnotations() (map[string]string, error) {
+	return doc.GetStringMap("metadata:annotations")
+}
+
+// AsYAML re-serializes the document's parsed contents back to YAML, which
+// normalizes formatting and key ordering relative to DocumentData.
+func (doc *KustomizationDocument) AsYAML() ([]byte, error) {
+	contents, err := doc.rootContents()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(contents)
+}
@@ -0,0 +1,123 @@
+package doc
+
+import "testing"
+
+const testManifest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-app
+  namespace: default
+  labels:
+    app: my-app
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        image: example.com/my-app:v1
+      - name: sidecar
+        image: example.com/sidecar:v1
+`
+
+func newTestKustomizationDoc(yamlContent string) *KustomizationDocument {
+	return &KustomizationDocument{
+		Document: Document{FilePath: "app/kustomization.yaml", DocumentData: yamlContent},
+	}
+}
+
+func TestGetFieldValue(t *testing.T) {
+	doc := newTestKustomizationDoc(testManifest)
+
+	cases := []struct {
+		path string
+		want interface{}
+	}{
+		{"kind", "Deployment"},
+		{"metadata:name", "my-app"},
+		{"spec:replicas", float64(3)},
+		{"spec:template:spec:containers[0]:name", "app"},
+		{"spec:template:spec:containers[1]:name", "sidecar"},
+	}
+
+	for _, c := range cases {
+		got, err := doc.GetFieldValue(c.path)
+		if err != nil {
+			t.Errorf("GetFieldValue(%q) returned error: %v", c.path, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("GetFieldValue(%q) = %v, want %v", c.path, got, c.want)
+		}
+	}
+}
+
+func TestGetFieldValueErrors(t *testing.T) {
+	doc := newTestKustomizationDoc(testManifest)
+
+	cases := []string{
+		"spec:doesNotExist",
+		"spec:template:spec:containers[5]:name",
+		"spec:replicas:nested",
+	}
+	for _, path := range cases {
+		if _, err := doc.GetFieldValue(path); err == nil {
+			t.Errorf("GetFieldValue(%q) expected an error, got none", path)
+		}
+	}
+}
+
+func TestTypedGetters(t *testing.T) {
+	doc := newTestKustomizationDoc(testManifest)
+
+	if got, err := doc.GetInt64("spec:replicas"); err != nil || got != 3 {
+		t.Errorf("GetInt64(spec:replicas) = %v, %v, want 3, nil", got, err)
+	}
+	if got, err := doc.GetStringSlice("spec:template:spec:containers[0]:name"); err == nil {
+		t.Errorf("GetStringSlice on a scalar field unexpectedly succeeded: %v", got)
+	}
+	if got, err := doc.GetLabels(); err != nil || got["app"] != "my-app" {
+		t.Errorf("GetLabels() = %v, %v, want map with app=my-app", got, err)
+	}
+}
+
+func TestGetKindDefaultsWhenAbsent(t *testing.T) {
+	doc := newTestKustomizationDoc("resources:\n- foo.yaml\n")
+
+	kind, err := doc.GetKind()
+	if err != nil {
+		t.Fatalf("GetKind() returned error: %v", err)
+	}
+	if kind != "Kustomization" {
+		t.Errorf("GetKind() = %q, want %q", kind, "Kustomization")
+	}
+}
+
+func TestGetGroup(t *testing.T) {
+	doc := newTestKustomizationDoc(testManifest)
+	group, err := doc.GetGroup()
+	if err != nil {
+		t.Fatalf("GetGroup() returned error: %v", err)
+	}
+	if group != "apps" {
+		t.Errorf("GetGroup() = %q, want %q", group, "apps")
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	segments, err := parsePath("spec:containers[0][1]:image")
+	if err != nil {
+		t.Fatalf("parsePath returned error: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("parsePath returned %d segments, want 2", len(segments))
+	}
+	if segments[0].key != "spec" || len(segments[0].indices) != 0 {
+		t.Errorf("segments[0] = %+v, want key=spec with no indices", segments[0])
+	}
+	if segments[1].key != "containers" || len(segments[1].indices) != 2 ||
+		segments[1].indices[0] != 0 || segments[1].indices[1] != 1 {
+		t.Errorf("segments[1] = %+v, want key=containers with indices [0 1]", segments[1])
+	}
+}
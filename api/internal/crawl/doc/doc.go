@@ -42,6 +42,29 @@ type KustomizationDocument struct {
 	Kinds       []string `json:"kinds,omitempty"`
 	Identifiers []string `json:"identifiers,omitempty"`
 	Values      []string `json:"values,omitempty"`
+
+	// SubtreeHash is a stable SHA-256 over this document's own contents
+	// and the SubtreeHash of every child collected by ExpandRecursively,
+	// so that a change anywhere below this node changes its hash too.
+	// It is empty until ExpandRecursively has visited this node.
+	SubtreeHash string `json:"subtreeHash,omitempty"`
+
+	// Loader resolves the content of this document and its children. It
+	// is nil for documents whose DocumentData was already populated by
+	// the caller (e.g. a crawler that fetched file content itself), and
+	// set for documents that should fetch their own and their children's
+	// content on demand, including across remote refs.
+	Loader Loader `json:"-"`
+
+	// parsed caches the maps produced by readBytes so that the typed
+	// field accessors in accessor.go don't have to re-parse DocumentData on every
+	// call. It is populated lazily by parsedContents.
+	parsed []map[string]interface{}
+
+	// children holds the documents collected for this node the last time
+	// it was expanded by ExpandRecursively, so that Diff can walk the
+	// tree without re-running kustomization parsing.
+	children []*KustomizationDocument
 }
 
 type set map[string]struct{}
@@ -74,7 +97,7 @@ func IsKustomizationFile(path string) bool {
 
 // Implements the CrawlerDocument interface.
 func (doc *KustomizationDocument) GetResources(
-	includeResources, includeTransformers, includeGenerators bool) ([]*Document, error) {
+	includeResources, includeComponents, includeTransformers, includeGenerators bool) ([]*Document, error) {
 	if !IsKustomizationFile(doc.FilePath) {
 		return []*Document{}, nil
 	}
@@ -85,13 +108,10 @@ func (doc *KustomizationDocument) GetResources(
 		return nil, fmt.Errorf("could not fix kustomize file: %v", err)
 	}
 
-	var k types.Kustomization
-	err = yaml.Unmarshal(content, &k)
+	k, err := unmarshalKustomization(content)
 	if err != nil {
-		return nil, fmt.Errorf(
-			"could not parse kustomization: %v", err)
+		return nil, err
 	}
-	k.FixKustomizationPostUnmarshalling()
 
 	res := make([]*Document, 0)
 
@@ -100,6 +120,11 @@ func (doc *KustomizationDocument) GetResources(
 		res = append(res, resourceDocs...)
 	}
 
+	if includeComponents {
+		componentDocs := doc.CollectDocuments(k.Components, fileTypeComponent)
+		res = append(res, componentDocs...)
+	}
+
 	if includeGenerators {
 		generatorDocs := doc.CollectDocuments(k.Generators, "generator")
 		res = append(res, generatorDocs...)
@@ -110,9 +135,35 @@ func (doc *KustomizationDocument) GetResources(
 		res = append(res, transformerDocs...)
 	}
 
+	// These fields make up the rest of the modern Kustomization surface:
+	// patches, replacements, generator file/env sources, CRDs, the OpenAPI
+	// schema and configurations. Unlike resources/components/generators/
+	// transformers, these are surfaced purely for search indexing rather
+	// than for the caller to further expand, so they're always collected.
+	res = append(res, doc.collectPatchDocuments(k)...)
+	res = append(res, doc.collectReplacementDocuments(k)...)
+	res = append(res, doc.collectGeneratorSourceDocuments(k)...)
+	res = append(res, doc.CollectDocuments(k.Crds, fileTypeCrd)...)
+	if path := k.OpenAPI["path"]; path != "" {
+		res = append(res, doc.CollectDocuments([]string{path}, fileTypeOpenAPI)...)
+	}
+	res = append(res, doc.CollectDocuments(k.Configurations, fileTypeConfiguration)...)
+
 	return res, nil
 }
 
+// unmarshalKustomization parses already-fixed-up kustomization content into
+// a types.Kustomization, applying the same post-unmarshalling fixups
+// GetResources has always applied.
+func unmarshalKustomization(content []byte) (*types.Kustomization, error) {
+	var k types.Kustomization
+	if err := yaml.Unmarshal(content, &k); err != nil {
+		return nil, fmt.Errorf("could not parse kustomization: %v", err)
+	}
+	k.FixKustomizationPostUnmarshalling()
+	return &k, nil
+}
+
 // CollectDocuments construct a Document for each path in paths, and return
 // a slice of Document pointers.
 func (doc *KustomizationDocument) CollectDocuments(
@@ -122,6 +173,12 @@ func (doc *KustomizationDocument) CollectDocuments(
 		if strings.TrimSpace(r) == "" {
 			continue
 		}
+		if IsRemoteRef(r) {
+			// Remote refs aren't resolvable as a relative path against
+			// this repository; ExpandRecursively resolves them directly
+			// via a RemoteLoader instead.
+			continue
+		}
 		next, err := doc.Document.FromRelativePath(r)
 		if err != nil {
 			log.Printf("CollectDocuments error: %v\n", err)
@@ -134,6 +191,14 @@ func (doc *KustomizationDocument) CollectDocuments(
 }
 
 func (doc *KustomizationDocument) readBytes() ([]map[string]interface{}, error) {
+	if doc.DocumentData == "" && doc.Loader != nil {
+		content, err := doc.Loader.ReadFile(doc.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("could not load %s via loader: %v", doc.FilePath, err)
+		}
+		doc.DocumentData = string(content)
+	}
+
 	data := []byte(doc.DocumentData)
 
 	for _, suffix := range konfig.RecognizedKustomizationFileNames() {
@@ -161,6 +226,22 @@ func (doc *KustomizationDocument) readBytes() ([]map[string]interface{}, error)
 	return configs, nil
 }
 
+// parsedContents returns the parsed map(s) backing doc, reading and caching
+// them via readBytes on first use. The typed field accessors in
+// accessor.go are built on top of this cache rather than DocumentData
+// directly so that repeated GetX calls don't each re-parse the YAML.
+func (doc *KustomizationDocument) parsedContents() ([]map[string]interface{}, error) {
+	if doc.parsed != nil {
+		return doc.parsed, nil
+	}
+	ks, err := doc.readBytes()
+	if err != nil {
+		return nil, err
+	}
+	doc.parsed = ks
+	return doc.parsed, nil
+}
+
 // ParseYAML parses doc.Document and sets the following fields of doc:
 // Kinds, Values, Identifiers.
 func (doc *KustomizationDocument) ParseYAML() error {
@@ -184,7 +265,7 @@ func (doc *KustomizationDocument) ParseYAML() error {
 		return defaultStr
 	}
 
-	ks, err := doc.readBytes()
+	ks, err := doc.parsedContents()
 	if err != nil {
 		return err
 	}
@@ -194,6 +275,10 @@ func (doc *KustomizationDocument) ParseYAML() error {
 		createFlatStructure(identifierSet, valueSet, contents)
 	}
 
+	if IsKustomizationFile(doc.FilePath) {
+		doc.addSyntheticIdentifiers(identifierSet, []byte(doc.DocumentData))
+	}
+
 	for val := range kindSet {
 		doc.Kinds = append(doc.Kinds, val)
 	}
@@ -0,0 +1,293 @@
+package doc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+)
+
+// ExpandOptions controls how ExpandRecursively walks a kustomization tree.
+// Content is fetched through each document's Loader rather than through
+// ExpandOptions itself, so the same options work whether doc was built from
+// an on-disk checkout, an in-memory fixture, or a remote ref.
+type ExpandOptions struct {
+	// IncludeResources, IncludeComponents, IncludeBases, IncludeGenerators
+	// and IncludeTransformers mirror the flags GetResources already takes,
+	// scoped to which kustomization fields are followed during expansion.
+	IncludeResources    bool
+	IncludeComponents   bool
+	IncludeBases        bool
+	IncludeGenerators   bool
+	IncludeTransformers bool
+}
+
+// ExpandedNode is one node of a recursively expanded kustomization tree,
+// pairing the node's document with its position relative to its parent.
+type ExpandedNode struct {
+	*KustomizationDocument
+	ParentFilePath string
+	Depth          int
+}
+
+// visitKey identifies a single node for cycle detection. Ref distinguishes
+// otherwise-identical paths resolved through different remote refs.
+type visitKey struct {
+	RepositoryURL string
+	FilePath      string
+	Ref           string
+}
+
+// ExpandRecursively walks the full kustomization tree rooted at doc,
+// following resources, components, bases, generators, transformers and
+// remote refs, mirroring the recursive-build behavior `flux build
+// kustomization --recursive` added for Flux builds. It returns the
+// flattened list of visited nodes in pre-order, with each node's
+// ParentFilePath recording the edge back to its parent.
+//
+// As a side effect, every visited document has its SubtreeHash populated: a
+// stable SHA-256 over the document's own canonicalized YAML and its
+// children's SubtreeHash values, so that any change deep in the tree
+// bubbles up to the root. Diff uses these hashes to short-circuit
+// unchanged branches.
+func (doc *KustomizationDocument) ExpandRecursively(ctx context.Context, opts ExpandOptions) ([]*ExpandedNode, error) {
+	visited := make(map[visitKey]struct{})
+	var nodes []*ExpandedNode
+	if _, err := doc.expand(ctx, opts, "", 0, visited, &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func (doc *KustomizationDocument) expand(
+	ctx context.Context, opts ExpandOptions, parentFilePath string, depth int,
+	visited map[visitKey]struct{}, nodes *[]*ExpandedNode,
+) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+	}
+
+	key := visitKey{RepositoryURL: doc.RepositoryURL, FilePath: doc.FilePath, Ref: doc.DefaultBranch}
+	if _, ok := visited[key]; ok {
+		return "", fmt.Errorf("cycle detected while expanding %s (%s)", doc.FilePath, doc.RepositoryURL)
+	}
+	visited[key] = struct{}{}
+	defer delete(visited, key)
+
+	if doc.DocumentData == "" && doc.Loader != nil {
+		content, err := doc.Loader.ReadFile(doc.FilePath)
+		if err != nil {
+			return "", fmt.Errorf("could not load %s via loader: %v", doc.FilePath, err)
+		}
+		doc.DocumentData = string(content)
+	}
+
+	*nodes = append(*nodes, &ExpandedNode{KustomizationDocument: doc, ParentFilePath: parentFilePath, Depth: depth})
+
+	children, err := doc.collectExpansionChildren(opts)
+	if err != nil {
+		return "", err
+	}
+	doc.children = make([]*KustomizationDocument, 0, len(children))
+
+	childHashes := make([]string, 0, len(children))
+	for _, childDoc := range children {
+		hash, err := childDoc.expand(ctx, opts, doc.FilePath, depth+1, visited, nodes)
+		if err != nil {
+			return "", err
+		}
+		doc.children = append(doc.children, childDoc)
+		childHashes = append(childHashes, hash)
+	}
+
+	sort.Strings(childHashes)
+	doc.SubtreeHash = hashSubtree(doc.canonicalYAML(), childHashes)
+	return doc.SubtreeHash, nil
+}
+
+// canonicalYAML returns doc's contents re-serialized through AsYAML, so
+// that cosmetic differences (key order, whitespace, comments) don't change
+// SubtreeHash the way a raw DocumentData hash would. If doc can't be
+// parsed, DocumentData is hashed as-is rather than failing the whole
+// expansion over one unparsable document.
+func (doc *KustomizationDocument) canonicalYAML() string {
+	canonical, err := doc.AsYAML()
+	if err != nil {
+		log.Printf("canonicalYAML: could not canonicalize %s, hashing raw contents: %v\n", doc.FilePath, err)
+		return doc.DocumentData
+	}
+	return string(canonical)
+}
+
+// expansionField pairs one kustomization field's entries with the FileType
+// its children should be tagged with and whether ExpandOptions selected it.
+type expansionField struct {
+	paths    []string
+	fileType string
+	include  bool
+}
+
+// collectExpansionChildren gathers the child documents to recurse into,
+// reusing the same kustomization parsing GetResources does. Local children
+// inherit doc.Loader; remote refs (e.g. "github.com/org/repo//path?ref=v1")
+// each get their own Loader resolved by cloning the ref.
+func (doc *KustomizationDocument) collectExpansionChildren(opts ExpandOptions) ([]*KustomizationDocument, error) {
+	if !IsKustomizationFile(doc.FilePath) {
+		return nil, nil
+	}
+
+	content, err := FixKustomizationPreUnmarshallingNonFatal([]byte(doc.DocumentData))
+	if err != nil {
+		return nil, fmt.Errorf("could not fix kustomize file: %v", err)
+	}
+
+	k, err := unmarshalKustomization(content)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []expansionField{
+		{k.Resources, "resource", opts.IncludeResources},
+		{k.Components, "component", opts.IncludeComponents},
+		{k.Bases, "base", opts.IncludeBases}, //nolint:staticcheck // Bases is deprecated but still a valid kustomization field.
+		{k.Generators, "generator", opts.IncludeGenerators},
+		{k.Transformers, "transformer", opts.IncludeTransformers},
+	}
+
+	children := make([]*KustomizationDocument, 0)
+	for _, f := range fields {
+		if !f.include {
+			continue
+		}
+		for _, ref := range f.paths {
+			child, err := doc.resolveExpansionChild(ref, f.fileType)
+			if err != nil {
+				return nil, err
+			}
+			if child != nil {
+				children = append(children, child)
+			}
+		}
+	}
+	return children, nil
+}
+
+// resolveExpansionChild resolves a single resources/components/bases/
+// generators/transformers entry into a child document ready to be expanded,
+// or nil if ref is blank.
+func (doc *KustomizationDocument) resolveExpansionChild(ref, fileType string) (*KustomizationDocument, error) {
+	if strings.TrimSpace(ref) == "" {
+		return nil, nil
+	}
+
+	if IsRemoteRef(ref) {
+		repoURL, subPath, gitRef, err := parseRemoteRef(ref)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve remote ref %s: %v", ref, err)
+		}
+		loader, err := NewRemoteLoader(ref, remoteCacheDir())
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve remote ref %s: %v", ref, err)
+		}
+		return &KustomizationDocument{
+			Document: Document{
+				RepositoryURL: repoURL,
+				FilePath:      filepath.Join(subPath, konfig.DefaultKustomizationFileName()),
+				DefaultBranch: gitRef,
+				FileType:      fileType,
+			},
+			Loader: loader,
+		}, nil
+	}
+
+	docs := doc.CollectDocuments([]string{ref}, fileType)
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	return &KustomizationDocument{Document: *docs[0], Loader: doc.Loader}, nil
+}
+
+// remoteCacheDir is where remote refs are cloned to. It is a var rather
+// than a constant so tests can point it at a temp directory.
+var remoteCacheDir = func() string {
+	return filepath.Join(os.TempDir(), "kustomize-crawl-remote-cache")
+}
+
+func hashSubtree(canonicalYAML string, childHashes []string) string {
+	h := sha256.New()
+	h.Write([]byte(canonicalYAML))
+	for _, childHash := range childHashes {
+		h.Write([]byte(childHash))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DocumentChange describes a single subtree whose contents differ between
+// two recursively-expanded kustomization trees.
+type DocumentChange struct {
+	FilePath string
+	OldHash  string
+	NewHash  string
+	Added    bool
+	Removed  bool
+}
+
+// Diff compares the recursively expanded trees rooted at doc and other,
+// both of which must already have been passed to ExpandRecursively. It
+// walks the trees in lockstep by FilePath, using SubtreeHash to
+// short-circuit any branch whose hash is unchanged, so re-indexing a repo
+// after a small edit costs O(changed subtree) rather than O(repo).
+func (doc *KustomizationDocument) Diff(other *KustomizationDocument) ([]DocumentChange, error) {
+	if doc.SubtreeHash == "" || other.SubtreeHash == "" {
+		return nil, fmt.Errorf("both documents must be expanded with ExpandRecursively before diffing")
+	}
+	return doc.diff(other), nil
+}
+
+func (doc *KustomizationDocument) diff(other *KustomizationDocument) []DocumentChange {
+	if doc.SubtreeHash == other.SubtreeHash {
+		return nil
+	}
+
+	var changes []DocumentChange
+
+	if doc.canonicalYAML() != other.canonicalYAML() {
+		changes = append(changes, DocumentChange{FilePath: doc.FilePath, OldHash: other.SubtreeHash, NewHash: doc.SubtreeHash})
+	}
+
+	byPath := make(map[string]*KustomizationDocument, len(other.children))
+	for _, child := range other.children {
+		byPath[child.FilePath] = child
+	}
+
+	seen := make(map[string]struct{}, len(doc.children))
+	for _, child := range doc.children {
+		seen[child.FilePath] = struct{}{}
+		match, ok := byPath[child.FilePath]
+		if !ok {
+			changes = append(changes, DocumentChange{FilePath: child.FilePath, NewHash: child.SubtreeHash, Added: true})
+			continue
+		}
+		if child.SubtreeHash == match.SubtreeHash {
+			continue
+		}
+		changes = append(changes, child.diff(match)...)
+	}
+
+	for _, child := range other.children {
+		if _, ok := seen[child.FilePath]; !ok {
+			changes = append(changes, DocumentChange{FilePath: child.FilePath, OldHash: child.SubtreeHash, Removed: true})
+		}
+	}
+
+	return changes
+}
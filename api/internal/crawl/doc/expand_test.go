@@ -0,0 +1,163 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func newExpandableDoc(filePath, content string) *KustomizationDocument {
+	return &KustomizationDocument{Document: Document{FilePath: filePath, DocumentData: content}}
+}
+
+// expandOpts is the ExpandOptions used by these tests: every kustomization
+// field ExpandRecursively understands is followed.
+var expandOpts = ExpandOptions{
+	IncludeResources:    true,
+	IncludeComponents:   true,
+	IncludeBases:        true,
+	IncludeGenerators:   true,
+	IncludeTransformers: true,
+}
+
+func TestExpandRecursivelySetsSubtreeHash(t *testing.T) {
+	root := newExpandableDoc("kustomization.yaml", "resources:\n- deployment.yaml\n")
+	root.Loader = newFixtureLoader(map[string]string{
+		"deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n",
+	})
+
+	nodes, err := root.ExpandRecursively(context.Background(), expandOpts)
+	if err != nil {
+		t.Fatalf("ExpandRecursively returned error: %v", err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("ExpandRecursively returned %d nodes, want 2", len(nodes))
+	}
+	if root.SubtreeHash == "" {
+		t.Error("root.SubtreeHash is empty after ExpandRecursively")
+	}
+	if nodes[1].ParentFilePath != root.FilePath {
+		t.Errorf("child ParentFilePath = %q, want %q", nodes[1].ParentFilePath, root.FilePath)
+	}
+}
+
+func TestSubtreeHashIgnoresCosmeticChanges(t *testing.T) {
+	const resource = "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n"
+
+	// The kustomization sits in a directory: readBytes only parses a path
+	// ending in "/kustomization.yaml" as a kustomization.
+	a := newExpandableDoc("app/kustomization.yaml", "resources:\n- deployment.yaml\n")
+	a.Loader = newFixtureLoader(map[string]string{"app/deployment.yaml": resource})
+	if _, err := a.ExpandRecursively(context.Background(), expandOpts); err != nil {
+		t.Fatalf("ExpandRecursively(a) returned error: %v", err)
+	}
+
+	// Same content, different list style and spacing: a reformat, not a
+	// semantic change.
+	b := newExpandableDoc("app/kustomization.yaml", "resources:   [deployment.yaml]\n")
+	b.Loader = newFixtureLoader(map[string]string{"app/deployment.yaml": resource})
+	if _, err := b.ExpandRecursively(context.Background(), expandOpts); err != nil {
+		t.Fatalf("ExpandRecursively(b) returned error: %v", err)
+	}
+
+	if a.SubtreeHash != b.SubtreeHash {
+		t.Errorf("SubtreeHash differs across a cosmetic reformat: %q vs %q", a.SubtreeHash, b.SubtreeHash)
+	}
+}
+
+func TestSubtreeHashChangesWithChildContent(t *testing.T) {
+	a := newExpandableDoc("kustomization.yaml", "resources:\n- deployment.yaml\n")
+	a.Loader = newFixtureLoader(map[string]string{
+		"deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n",
+	})
+	if _, err := a.ExpandRecursively(context.Background(), expandOpts); err != nil {
+		t.Fatalf("ExpandRecursively(a) returned error: %v", err)
+	}
+
+	b := newExpandableDoc("kustomization.yaml", "resources:\n- deployment.yaml\n")
+	b.Loader = newFixtureLoader(map[string]string{
+		"deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  replicas: 2\n",
+	})
+	if _, err := b.ExpandRecursively(context.Background(), expandOpts); err != nil {
+		t.Fatalf("ExpandRecursively(b) returned error: %v", err)
+	}
+
+	if a.SubtreeHash == b.SubtreeHash {
+		t.Error("SubtreeHash did not change when a child's contents changed")
+	}
+
+	changes, err := a.Diff(b)
+	if err != nil {
+		t.Fatalf("Diff returned error: %v", err)
+	}
+	if len(changes) == 0 {
+		t.Error("Diff found no changes between trees with different child content")
+	}
+}
+
+func TestDiffShortCircuitsUnchangedTree(t *testing.T) {
+	root := newExpandableDoc("kustomization.yaml", "resources:\n- deployment.yaml\n")
+	root.Loader = newFixtureLoader(map[string]string{
+		"deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n",
+	})
+	if _, err := root.ExpandRecursively(context.Background(), expandOpts); err != nil {
+		t.Fatalf("ExpandRecursively returned error: %v", err)
+	}
+
+	changes, err := root.Diff(root)
+	if err != nil {
+		t.Fatalf("Diff returned error: %v", err)
+	}
+	if changes != nil {
+		t.Errorf("Diff(doc, doc) = %v, want nil", changes)
+	}
+}
+
+func TestExpandRecursivelyDetectsCycles(t *testing.T) {
+	// A kustomization that lists itself as a resource is the simplest
+	// possible cycle, independent of how relative paths are resolved.
+	root := newExpandableDoc("kustomization.yaml", "resources:\n- kustomization.yaml\n")
+	root.Loader = newFixtureLoader(map[string]string{
+		"kustomization.yaml": "resources:\n- kustomization.yaml\n",
+	})
+
+	if _, err := root.ExpandRecursively(context.Background(), expandOpts); err == nil {
+		t.Error("ExpandRecursively did not return an error for a cyclic tree")
+	}
+}
+
+// fixtureLoader is a minimal in-memory Loader for tests that don't need the
+// full filesys-backed implementations in loader_fsys.go.
+type fixtureLoader struct {
+	files map[string]string
+}
+
+func newFixtureLoader(files map[string]string) Loader {
+	return &fixtureLoader{files: files}
+}
+
+func (l *fixtureLoader) Open(path string) (io.ReadCloser, error) {
+	return nil, errors.New("Open is not implemented by fixtureLoader")
+}
+
+func (l *fixtureLoader) ReadFile(path string) ([]byte, error) {
+	content, ok := l.files[path]
+	if !ok {
+		return nil, errors.New("file not found: " + path)
+	}
+	return []byte(content), nil
+}
+
+func (l *fixtureLoader) Exists(path string) bool {
+	_, ok := l.files[path]
+	return ok
+}
+
+func (l *fixtureLoader) Root() string { return "" }
+
+func (l *fixtureLoader) Chroot(path string) (Loader, error) {
+	return l, nil
+}
+
+func (l *fixtureLoader) IsRemote() bool { return false }
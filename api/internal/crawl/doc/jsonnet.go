@@ -0,0 +1,322 @@
+package doc
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	jsonnet "github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+	"sigs.k8s.io/yaml"
+)
+
+// jsonnetExtensions are the file suffixes recognized as jsonnet sources.
+// ".libsonnet" files are importable libraries rather than entrypoints, but
+// the crawler still indexes them directly since they're often searched for
+// on their own (e.g. a shared mixin).
+var jsonnetExtensions = []string{".jsonnet", ".libsonnet"}
+
+// IsJsonnetFile determines whether a file path is a jsonnet source, the
+// sibling of IsKustomizationFile for the JsonnetDocument document type.
+func IsJsonnetFile(path string) bool {
+	for _, ext := range jsonnetExtensions {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// JsonnetDocument is the sibling of KustomizationDocument for cluster
+// configuration authored as jsonnet (kubecfg, kartongips and similar
+// tools), rather than plain kustomize YAML. It implements the same
+// CrawlerDocument interface as KustomizationDocument: evaluating the
+// jsonnet source stands in for parsing YAML, and the resulting manifests
+// are fed through the same createFlatStructure used for Kinds/Identifiers/
+// Values so both document types are searchable the same way.
+//
+// GetResources surfaces every import/importstr (local or remote) as a
+// child *Document, so the crawler still discovers and indexes a jsonnet
+// tree's full dependency graph. JsonnetDocument does not yet implement
+// ExpandRecursively/SubtreeHash/Diff itself, though, so unlike
+// KustomizationDocument, jsonnet trees aren't covered by the hash-based
+// recursive expansion and incremental-diff machinery in expand.go.
+type JsonnetDocument struct {
+	Document
+	Kinds       []string `json:"kinds,omitempty"`
+	Identifiers []string `json:"identifiers,omitempty"`
+	Values      []string `json:"values,omitempty"`
+
+	// Loader resolves import/importstr targets, including across remote
+	// refs, the same way it resolves a KustomizationDocument's children.
+	Loader Loader `json:"-"`
+
+	// Unindexable is set when jsonnet evaluation failed, so that one bad
+	// jsonnet file marks itself unindexable instead of aborting the crawl
+	// for every other document in the repository.
+	Unindexable       bool   `json:"unindexable,omitempty"`
+	UnindexableReason string `json:"unindexableReason,omitempty"`
+}
+
+func (doc *JsonnetDocument) Copy() *JsonnetDocument {
+	return &JsonnetDocument{
+		Document:          *(doc.Document.Copy()),
+		Kinds:             doc.Kinds,
+		Identifiers:       doc.Identifiers,
+		Values:            doc.Values,
+		Unindexable:       doc.Unindexable,
+		UnindexableReason: doc.UnindexableReason,
+	}
+}
+
+func (doc *JsonnetDocument) String() string {
+	return fmt.Sprintf("%s %s %s %v %v %v len(identifiers):%v len(values):%v",
+		doc.RepositoryURL, doc.FilePath, doc.DefaultBranch, doc.CreationTime,
+		doc.IsSame, doc.Kinds, len(doc.Identifiers), len(doc.Values))
+}
+
+// Implements the CrawlerDocument interface.
+func (doc *JsonnetDocument) GetResources(
+	includeResources, includeComponents, includeTransformers, includeGenerators bool) ([]*Document, error) {
+	if !IsJsonnetFile(doc.FilePath) {
+		return []*Document{}, nil
+	}
+
+	imports := newImportTracker(doc)
+	vm := doc.newVM(imports)
+
+	if _, err := vm.EvaluateAnonymousSnippet(doc.FilePath, doc.DocumentData); err != nil {
+		// Evaluating for side effects (collecting imports) only; a real
+		// evaluation error is reported by ParseYAML, which actually needs
+		// the output. Here we still want whatever imports were resolved
+		// before the error so the dependency graph is as complete as
+		// possible.
+		log.Printf("GetResources: %s failed to evaluate: %v\n", doc.FilePath, err)
+	}
+
+	docs := make([]*Document, 0, len(imports.paths))
+	for _, p := range imports.paths {
+		if IsRemoteRef(p) {
+			repoURL, subPath, gitRef, err := parseRemoteRef(p)
+			if err != nil {
+				log.Printf("GetResources error resolving remote import %q: %v\n", p, err)
+				continue
+			}
+			docs = append(docs, &Document{
+				RepositoryURL: repoURL,
+				FilePath:      subPath,
+				DefaultBranch: gitRef,
+				FileType:      "jsonnetImport",
+			})
+			continue
+		}
+		next, err := doc.Document.FromRelativePath(p)
+		if err != nil {
+			log.Printf("GetResources error resolving import %q: %v\n", p, err)
+			continue
+		}
+		next.FileType = "jsonnetImport"
+		docs = append(docs, &next)
+	}
+	return docs, nil
+}
+
+// ParseYAML evaluates doc's jsonnet source to a JSON/YAML manifest stream
+// and sets Kinds, Values and Identifiers the same way
+// KustomizationDocument.ParseYAML does for plain YAML. An evaluation
+// failure marks the document Unindexable instead of returning an error, so
+// one broken jsonnet file doesn't abort the rest of the crawl.
+func (doc *JsonnetDocument) ParseYAML() error {
+	doc.Identifiers = make([]string, 0)
+	doc.Values = make([]string, 0)
+	doc.Kinds = make([]string, 0, 1)
+
+	imports := newImportTracker(doc)
+	vm := doc.newVM(imports)
+
+	out, err := vm.EvaluateAnonymousSnippet(doc.FilePath, doc.DocumentData)
+	if err != nil {
+		doc.Unindexable = true
+		doc.UnindexableReason = err.Error()
+		return nil
+	}
+
+	manifests, err := manifestsFromJsonnetOutput(out)
+	if err != nil {
+		doc.Unindexable = true
+		doc.UnindexableReason = err.Error()
+		return nil
+	}
+
+	identifierSet := make(set)
+	valueSet := make(set)
+	kindSet := make(set)
+
+	for _, m := range manifests {
+		if kind, ok := m["kind"].(string); ok && kind != "" {
+			kindSet[kind] = struct{}{}
+		}
+		createFlatStructure(identifierSet, valueSet, m)
+	}
+
+	for val := range kindSet {
+		doc.Kinds = append(doc.Kinds, val)
+	}
+	for val := range valueSet {
+		doc.Values = append(doc.Values, val)
+	}
+	for key := range identifierSet {
+		doc.Identifiers = append(doc.Identifiers, key)
+	}
+
+	sort.Strings(doc.Kinds)
+	sort.Strings(doc.Values)
+	sort.Strings(doc.Identifiers)
+
+	return nil
+}
+
+// manifestsFromJsonnetOutput splits jsonnet's evaluated output (a single
+// JSON value, or a stream produced via `std.manifestYamlStream`-style
+// arrays) into the individual Kubernetes manifests it represents.
+func manifestsFromJsonnetOutput(out string) ([]map[string]interface{}, error) {
+	var single map[string]interface{}
+	if err := yaml.Unmarshal([]byte(out), &single); err == nil && len(single) > 0 {
+		return []map[string]interface{}{single}, nil
+	}
+
+	var list []map[string]interface{}
+	if err := yaml.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("jsonnet output is not a manifest or list of manifests: %v", err)
+	}
+	return list, nil
+}
+
+// newVM builds a jsonnet VM configured the way kubecfg-style forks
+// configure theirs: imports resolved through the document's Loader, and
+// parseYaml/parseJson/manifestYamlFromJson exposed as native functions so
+// jsonnet sources can round-trip YAML/JSON without shelling out.
+func (doc *JsonnetDocument) newVM(imports *importTracker) *jsonnet.VM {
+	vm := jsonnet.MakeVM()
+	vm.Importer(&loaderImporter{doc: doc, tracker: imports})
+
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "parseYaml",
+		Params: jsonnetParams("yaml"),
+		Func: func(args []interface{}) (interface{}, error) {
+			return parseStructuredArg(args, yaml.Unmarshal)
+		},
+	})
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "parseJson",
+		Params: jsonnetParams("json"),
+		Func: func(args []interface{}) (interface{}, error) {
+			return parseStructuredArg(args, yaml.Unmarshal)
+		},
+	})
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "manifestYamlFromJson",
+		Params: jsonnetParams("json"),
+		Func: func(args []interface{}) (interface{}, error) {
+			s, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("manifestYamlFromJson expects a string argument")
+			}
+			var v interface{}
+			if err := yaml.Unmarshal([]byte(s), &v); err != nil {
+				return nil, err
+			}
+			out, err := yaml.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			return string(out), nil
+		},
+	})
+
+	return vm
+}
+
+func jsonnetParams(name string) ast.Identifiers {
+	return ast.Identifiers{ast.Identifier(name)}
+}
+
+func parseStructuredArg(args []interface{}, unmarshal func([]byte, interface{}) error) (interface{}, error) {
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a string argument")
+	}
+	var v interface{}
+	if err := unmarshal([]byte(s), &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// importTracker records every file jsonnet's import/importstr resolved
+// during evaluation, so GetResources can surface them as children without
+// the crawler having to re-walk the AST itself.
+type importTracker struct {
+	doc   *JsonnetDocument
+	paths []string
+	seen  set
+}
+
+func newImportTracker(doc *JsonnetDocument) *importTracker {
+	return &importTracker{doc: doc, seen: make(set)}
+}
+
+func (t *importTracker) record(p string) {
+	if _, ok := t.seen[p]; ok {
+		return
+	}
+	t.seen[p] = struct{}{}
+	t.paths = append(t.paths, p)
+}
+
+// loaderImporter implements jsonnet.Importer on top of a Loader, so
+// import/importstr resolve through the same abstraction that resolves a
+// KustomizationDocument's children, including remote refs (e.g.
+// "github.com/org/repo//lib/foo.libsonnet?ref=v1"), which are cloned
+// through the same NewRemoteLoader used for remote resources/bases.
+type loaderImporter struct {
+	doc     *JsonnetDocument
+	tracker *importTracker
+}
+
+func (imp *loaderImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	if IsRemoteRef(importedPath) {
+		imp.tracker.record(importedPath)
+		loader, err := NewRemoteLoader(importedPath, remoteCacheDir())
+		if err != nil {
+			return jsonnet.Contents{}, "", fmt.Errorf("could not import %q: %v", importedPath, err)
+		}
+		content, err := loader.ReadFile("")
+		if err != nil {
+			return jsonnet.Contents{}, "", fmt.Errorf("could not import %q: %v", importedPath, err)
+		}
+		return jsonnet.MakeContents(string(content)), importedPath, nil
+	}
+
+	resolved := importedPath
+	if !path.IsAbs(resolved) {
+		resolved = path.Join(path.Dir(importedFrom), importedPath)
+	}
+	imp.tracker.record(resolved)
+
+	var content []byte
+	var err error
+	switch {
+	case imp.doc.Loader != nil:
+		content, err = imp.doc.Loader.ReadFile(resolved)
+	default:
+		err = fmt.Errorf("no loader configured to resolve import %q", importedPath)
+	}
+	if err != nil {
+		return jsonnet.Contents{}, "", fmt.Errorf("could not import %q: %v", importedPath, err)
+	}
+
+	return jsonnet.MakeContents(string(content)), resolved, nil
+}
@@ -0,0 +1,75 @@
+package doc
+
+import "testing"
+
+func TestIsJsonnetFile(t *testing.T) {
+	cases := map[string]bool{
+		"main.jsonnet":        true,
+		"lib/mixin.libsonnet": true,
+		"kustomization.yaml":  false,
+		"README.md":           false,
+		"":                    false,
+	}
+	for path, want := range cases {
+		if got := IsJsonnetFile(path); got != want {
+			t.Errorf("IsJsonnetFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestManifestsFromJsonnetOutputSingle(t *testing.T) {
+	out := `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"my-app"}}`
+	manifests, err := manifestsFromJsonnetOutput(out)
+	if err != nil {
+		t.Fatalf("manifestsFromJsonnetOutput returned error: %v", err)
+	}
+	if len(manifests) != 1 {
+		t.Fatalf("manifestsFromJsonnetOutput returned %d manifests, want 1", len(manifests))
+	}
+	if manifests[0]["kind"] != "Deployment" {
+		t.Errorf("manifests[0][kind] = %v, want Deployment", manifests[0]["kind"])
+	}
+}
+
+func TestManifestsFromJsonnetOutputList(t *testing.T) {
+	out := `[
+		{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"}},
+		{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b"}}
+	]`
+	manifests, err := manifestsFromJsonnetOutput(out)
+	if err != nil {
+		t.Fatalf("manifestsFromJsonnetOutput returned error: %v", err)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("manifestsFromJsonnetOutput returned %d manifests, want 2", len(manifests))
+	}
+}
+
+func TestManifestsFromJsonnetOutputInvalid(t *testing.T) {
+	if _, err := manifestsFromJsonnetOutput(`"just a string"`); err == nil {
+		t.Error("manifestsFromJsonnetOutput(a bare string) expected an error, got none")
+	}
+}
+
+func TestLoaderImporterResolvesRelativeImport(t *testing.T) {
+	doc := &JsonnetDocument{Document: Document{FilePath: "main.jsonnet"}}
+	doc.Loader = newFixtureLoader(map[string]string{
+		"lib/mixin.libsonnet": "{ hello: 'world' }",
+	})
+	tracker := newImportTracker(doc)
+	imp := &loaderImporter{doc: doc, tracker: tracker}
+
+	contents, resolved, err := imp.Import("main.jsonnet", "lib/mixin.libsonnet")
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if resolved != "lib/mixin.libsonnet" {
+		t.Errorf("resolved = %q, want %q", resolved, "lib/mixin.libsonnet")
+	}
+	if contents.String() != "{ hello: 'world' }" {
+		t.Errorf("contents = %q, want the fixture file's content", contents.String())
+	}
+	if len(tracker.paths) != 1 || tracker.paths[0] != "lib/mixin.libsonnet" {
+		t.Errorf("tracker.paths = %v, want [lib/mixin.libsonnet]", tracker.paths)
+	}
+}
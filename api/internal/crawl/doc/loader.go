@@ -0,0 +1,31 @@
+package doc
+
+import "io"
+
+// Loader abstracts the filesystem that a Document's content, and its
+// children's content, are read from. It is modeled after
+// sigs.k8s.io/kustomize/api/filesys.FileSystem and the fSys parameter the
+// KustomizeVisitor change plumbed through kubectl, so that kustomization
+// parsing doesn't have to care whether it's reading a local checkout, an
+// in-memory fixture, or a remote ref.
+//
+// Paths passed to Loader methods are always relative to Root().
+type Loader interface {
+	// Open returns a reader for the file at path.
+	Open(path string) (io.ReadCloser, error)
+	// ReadFile returns the full contents of the file at path.
+	ReadFile(path string) ([]byte, error)
+	// Exists reports whether path names a file or directory.
+	Exists(path string) bool
+	// Root returns the directory this Loader resolves relative paths
+	// against.
+	Root() string
+	// Chroot returns a new Loader rooted at path, which may be relative
+	// to the current Root(). Expansion does not call it: children share
+	// their parent's Loader, and remote refs get a Loader of their own.
+	Chroot(path string) (Loader, error)
+	// IsRemote reports whether this Loader resolves paths over the
+	// network (a git or HTTP ref) rather than against local disk or
+	// memory, so callers can decide whether to cache its reads.
+	IsRemote() bool
+}
@@ -0,0 +1,63 @@
+package doc
+
+import (
+	"io"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+// fsysLoader implements Loader on top of sigs.k8s.io/kustomize/api/filesys,
+// the same filesystem abstraction kustomize's own build pipeline uses. It
+// backs both the on-disk and in-memory Loaders: the only difference between
+// the two is which filesys.FileSystem constructor is used.
+type fsysLoader struct {
+	fSys filesys.FileSystem
+	root string
+}
+
+// NewOnDiskLoader returns a Loader that reads from the real filesystem,
+// rooted at root, for indexing local checkouts.
+func NewOnDiskLoader(root string) Loader {
+	return &fsysLoader{fSys: filesys.MakeFsOnDisk(), root: root}
+}
+
+// NewInMemoryLoader returns a Loader backed by an in-memory filesystem,
+// rooted at root, for unit-testing the parser without touching disk or the
+// network. Callers populate it directly via the returned filesys.FileSystem
+// before handing it to a KustomizationDocument.
+func NewInMemoryLoader(root string) (Loader, filesys.FileSystem) {
+	fSys := filesys.MakeFsInMemory()
+	return &fsysLoader{fSys: fSys, root: root}, fSys
+}
+
+func (l *fsysLoader) Open(path string) (io.ReadCloser, error) {
+	return l.fSys.Open(l.abs(path))
+}
+
+func (l *fsysLoader) ReadFile(path string) ([]byte, error) {
+	return l.fSys.ReadFile(l.abs(path))
+}
+
+func (l *fsysLoader) Exists(path string) bool {
+	return l.fSys.Exists(l.abs(path))
+}
+
+func (l *fsysLoader) Root() string {
+	return l.root
+}
+
+func (l *fsysLoader) Chroot(path string) (Loader, error) {
+	return &fsysLoader{fSys: l.fSys, root: l.abs(path)}, nil
+}
+
+func (l *fsysLoader) IsRemote() bool {
+	return false
+}
+
+func (l *fsysLoader) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(l.root, path)
+}
@@ -0,0 +1,158 @@
+package doc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// remoteRefRegexp matches kustomize-style remote resource refs, e.g.
+// "github.com/org/repo//path/to/dir?ref=v1.2.3". The host+repo portion is
+// cloned as a git URL, "//path" (optional) selects a subdirectory within
+// the clone, and "?ref=" (optional) selects a branch, tag or commit.
+var remoteRefRegexp = regexp.MustCompile(`^(?P<repo>[^?]+?)(?://(?P<path>[^?]*))?(?:\?ref=(?P<ref>.+))?$`)
+
+// IsRemoteRef reports whether path looks like a kustomize-style remote
+// resource reference rather than a local relative path.
+func IsRemoteRef(path string) bool {
+	for _, host := range []string{"github.com/", "gitlab.com/", "bitbucket.org/"} {
+		if len(path) >= len(host) && path[:len(host)] == host {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteLoader implements Loader for a single kustomize-style remote ref by
+// shallow-cloning it into a local cache directory and delegating to an
+// on-disk Loader rooted at the ref's subdirectory.
+type remoteLoader struct {
+	Loader
+	cacheDir string
+}
+
+// NewRemoteLoader resolves ref (e.g. "github.com/org/repo//path?ref=v1")
+// by cloning it into a subdirectory of cacheDir, and returns a Loader
+// rooted at the ref's selected path. Clones are cached by ref so that
+// following the same remote resource from multiple kustomizations only
+// fetches it once.
+func NewRemoteLoader(ref, cacheDir string) (Loader, error) {
+	repo, subPath, gitRef, err := parseRemoteRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(cacheDir, cloneDirName(repo, gitRef))
+	if !isCloneComplete(dir) {
+		if err := cloneRepo(repo, gitRef, dir); err != nil {
+			return nil, fmt.Errorf("could not resolve remote ref %q: %v", ref, err)
+		}
+	}
+
+	root, err := safeJoinSubPath(dir, subPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve remote ref %q: %v", ref, err)
+	}
+	return &remoteLoader{Loader: NewOnDiskLoader(root), cacheDir: cacheDir}, nil
+}
+
+// safeJoinSubPath joins subPath onto dir and verifies the result is still
+// inside dir, rejecting a "//../../etc"-style subPath that would otherwise
+// let a remote ref's loader read files outside the clone. Remote refs are
+// parsed out of kustomization files found by crawling untrusted
+// repositories, so subPath must be treated as attacker-controlled.
+func safeJoinSubPath(dir, subPath string) (string, error) {
+	if subPath == "" {
+		return dir, nil
+	}
+	joined := filepath.Join(dir, subPath)
+	rel, err := filepath.Rel(dir, joined)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("sub-path %q escapes the cloned repository", subPath)
+	}
+	return joined, nil
+}
+
+func (l *remoteLoader) IsRemote() bool {
+	return true
+}
+
+func parseRemoteRef(ref string) (repo, subPath, gitRef string, err error) {
+	m := remoteRefRegexp.FindStringSubmatch(ref)
+	if m == nil {
+		return "", "", "", fmt.Errorf("%q is not a recognized remote ref", ref)
+	}
+	names := remoteRefRegexp.SubexpNames()
+	fields := make(map[string]string, len(names))
+	for i, name := range names {
+		if name != "" {
+			fields[name] = m[i]
+		}
+	}
+	if fields["repo"] == "" {
+		return "", "", "", fmt.Errorf("%q is missing a repository", ref)
+	}
+	return "https://" + fields["repo"] + ".git", fields["path"], fields["ref"], nil
+}
+
+func cloneDirName(repo, gitRef string) string {
+	h := sha256.Sum256([]byte(repo + "@" + gitRef))
+	return hex.EncodeToString(h[:])
+}
+
+// cloneCompleteMarker is written into a cache dir only once it holds a
+// fully resolved checkout, so a dir left behind by a clone that failed
+// partway through (e.g. a bad ref) is never mistaken for a cache hit.
+const cloneCompleteMarker = ".crawl-clone-complete"
+
+// cloneRepo clones repo into dest at gitRef. "--branch" only accepts a
+// branch or tag name, not an arbitrary commit SHA, so a shallow "--branch"
+// clone is tried first and, if git rejects the ref, falls back to a full
+// clone followed by a checkout of that ref. dest is wiped on any failure,
+// including a failed checkout, so a subsequent call starts clean rather
+// than reusing a partial or wrongly-resolved checkout.
+func cloneRepo(repo, gitRef, dest string) (err error) {
+	defer func() {
+		if err != nil {
+			os.RemoveAll(dest)
+			return
+		}
+		err = markCloneComplete(dest)
+	}()
+
+	if gitRef == "" {
+		return runGit("clone", "--depth", "1", repo, dest)
+	}
+	if err := runGit("clone", "--depth", "1", "--branch", gitRef, repo, dest); err == nil {
+		return nil
+	}
+	os.RemoveAll(dest)
+	if err := runGit("clone", repo, dest); err != nil {
+		return err
+	}
+	return runGit("-C", dest, "checkout", gitRef)
+}
+
+func markCloneComplete(dest string) error {
+	return ioutil.WriteFile(filepath.Join(dest, cloneCompleteMarker), []byte{}, 0o644)
+}
+
+func isCloneComplete(dest string) bool {
+	_, err := os.Stat(filepath.Join(dest, cloneCompleteMarker))
+	return err == nil
+}
+
+func runGit(args ...string) error {
+	cmd := exec.Command("git", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s failed: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return nil
+}
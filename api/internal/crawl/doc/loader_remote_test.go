@@ -0,0 +1,71 @@
+package doc
+
+import "testing"
+
+func TestIsRemoteRef(t *testing.T) {
+	cases := map[string]bool{
+		"github.com/org/repo//path?ref=v1": true,
+		"gitlab.com/org/repo":               true,
+		"bitbucket.org/org/repo":            true,
+		"../relative/path":                  false,
+		"resources/deployment.yaml":         false,
+		"":                                  false,
+	}
+	for ref, want := range cases {
+		if got := IsRemoteRef(ref); got != want {
+			t.Errorf("IsRemoteRef(%q) = %v, want %v", ref, got, want)
+		}
+	}
+}
+
+func TestParseRemoteRef(t *testing.T) {
+	cases := []struct {
+		ref      string
+		wantRepo string
+		wantPath string
+		wantRef  string
+	}{
+		{
+			ref:      "github.com/org/repo//overlays/prod?ref=v1.2.3",
+			wantRepo: "https://github.com/org/repo.git",
+			wantPath: "overlays/prod",
+			wantRef:  "v1.2.3",
+		},
+		{
+			ref:      "github.com/org/repo?ref=main",
+			wantRepo: "https://github.com/org/repo.git",
+			wantPath: "",
+			wantRef:  "main",
+		},
+		{
+			ref:      "github.com/org/repo",
+			wantRepo: "https://github.com/org/repo.git",
+			wantPath: "",
+			wantRef:  "",
+		},
+		{
+			ref:      "github.com/org/repo//path",
+			wantRepo: "https://github.com/org/repo.git",
+			wantPath: "path",
+			wantRef:  "",
+		},
+	}
+
+	for _, c := range cases {
+		repo, subPath, gitRef, err := parseRemoteRef(c.ref)
+		if err != nil {
+			t.Errorf("parseRemoteRef(%q) returned error: %v", c.ref, err)
+			continue
+		}
+		if repo != c.wantRepo || subPath != c.wantPath || gitRef != c.wantRef {
+			t.Errorf("parseRemoteRef(%q) = (%q, %q, %q), want (%q, %q, %q)",
+				c.ref, repo, subPath, gitRef, c.wantRepo, c.wantPath, c.wantRef)
+		}
+	}
+}
+
+func TestParseRemoteRefRejectsMissingRepo(t *testing.T) {
+	if _, _, _, err := parseRemoteRef("?ref=v1"); err == nil {
+		t.Error("parseRemoteRef(\"?ref=v1\") expected an error, got none")
+	}
+}
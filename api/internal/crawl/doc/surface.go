@@ -0,0 +1,253 @@
+package doc
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// FileType values for the parts of the modern Kustomization surface that
+// GetResources collects purely for search indexing: patches, replacements,
+// generator file/env sources, CRDs, the OpenAPI schema and configurations.
+const (
+	fileTypeComponent     = "component"
+	fileTypePatch         = "patch"
+	fileTypeReplacement   = "replacement"
+	fileTypeConfigMapFile = "configMapGenerator"
+	fileTypeSecretFile    = "secretGenerator"
+	fileTypeCrd           = "crd"
+	fileTypeOpenAPI       = "openapi"
+	fileTypeConfiguration = "configuration"
+)
+
+// collectPatchDocuments gathers the file-sourced entries of Patches,
+// PatchesStrategicMerge and PatchesJson6902. Inline patches (a literal YAML
+// or JSON6902 string instead of a path) don't name a file and are skipped.
+func (doc *KustomizationDocument) collectPatchDocuments(k *types.Kustomization) []*Document {
+	docs := make([]*Document, 0)
+
+	collect := func(path string) {
+		if strings.TrimSpace(path) == "" {
+			return
+		}
+		docs = append(docs, doc.CollectDocuments([]string{path}, fileTypePatch)...)
+	}
+
+	for _, p := range k.Patches {
+		collect(p.Path)
+	}
+	for _, p := range k.PatchesStrategicMerge {
+		// A multi-line entry is inline patch content, not a path.
+		if strings.Contains(string(p), "\n") {
+			continue
+		}
+		collect(string(p))
+	}
+	for _, p := range k.PatchesJson6902 {
+		collect(p.Path)
+	}
+
+	return docs
+}
+
+// collectReplacementDocuments gathers the file-sourced entries of
+// Replacements; replacement blocks defined inline in the kustomization
+// itself don't name a file and are skipped here; see
+// addSyntheticIdentifiers for how their source is still made searchable.
+func (doc *KustomizationDocument) collectReplacementDocuments(k *types.Kustomization) []*Document {
+	docs := make([]*Document, 0)
+	for _, r := range k.Replacements {
+		if strings.TrimSpace(r.Path) == "" {
+			continue
+		}
+		docs = append(docs, doc.CollectDocuments([]string{r.Path}, fileTypeReplacement)...)
+	}
+	return docs
+}
+
+// collectGeneratorSourceDocuments gathers the Files and Envs sources of
+// ConfigMapGenerator and SecretGenerator entries; Envs sources are file
+// paths too, just like Files. Literal key/value pairs aren't files and
+// aren't collected here.
+func (doc *KustomizationDocument) collectGeneratorSourceDocuments(k *types.Kustomization) []*Document {
+	docs := make([]*Document, 0)
+	for _, cm := range k.ConfigMapGenerator {
+		docs = append(docs, doc.collectFileSourceDocuments(cm.FileSources, fileTypeConfigMapFile)...)
+		docs = append(docs, doc.CollectDocuments(cm.EnvSources, fileTypeConfigMapFile)...)
+	}
+	for _, s := range k.SecretGenerator {
+		docs = append(docs, doc.collectFileSourceDocuments(s.FileSources, fileTypeSecretFile)...)
+		docs = append(docs, doc.CollectDocuments(s.EnvSources, fileTypeSecretFile)...)
+	}
+	return docs
+}
+
+// collectFileSourceDocuments resolves ConfigMapGenerator/SecretGenerator
+// "files" entries, which kustomize accepts either as a bare path or as
+// "key=path" (the key renames the resulting ConfigMap/Secret data entry).
+// Only the path half is resolvable as a file.
+func (doc *KustomizationDocument) collectFileSourceDocuments(sources []string, fileType string) []*Document {
+	paths := make([]string, 0, len(sources))
+	for _, src := range sources {
+		paths = append(paths, stripFileSourceKey(src))
+	}
+	return doc.CollectDocuments(paths, fileType)
+}
+
+// stripFileSourceKey strips an optional "key=" prefix from a FileSources
+// entry, returning just the path half.
+func stripFileSourceKey(fileSource string) string {
+	if parts := strings.SplitN(fileSource, "=", 2); len(parts) == 2 {
+		return parts[1]
+	}
+	return fileSource
+}
+
+// addSyntheticIdentifiers folds the parts of the Kustomization surface that
+// createFlatStructure can't see on its own into identifierSet: patch
+// targets and replacement sources, so that users can search for e.g.
+// "everything that patches Deployment X" even though the GVKN being
+// targeted never appears as a literal key in the kustomization YAML.
+func (doc *KustomizationDocument) addSyntheticIdentifiers(identifierSet set, content []byte) {
+	content, err := FixKustomizationPreUnmarshallingNonFatal(content)
+	if err != nil {
+		return
+	}
+	k, err := unmarshalKustomization(content)
+	if err != nil {
+		return
+	}
+
+	for _, p := range k.Patches {
+		addPatchTargetIdentifier(identifierSet, p.Target)
+	}
+	for _, p := range k.PatchesJson6902 {
+		addPatchTargetIdentifier(identifierSet, p.Target)
+	}
+	for _, p := range k.PatchesStrategicMerge {
+		doc.addStrategicMergePatchTargetIdentifier(identifierSet, string(p))
+	}
+
+	for _, r := range k.Replacements {
+		if r.ReplacementTransformer == nil {
+			continue
+		}
+		for _, repl := range r.Replacements {
+			addReplacementSourceIdentifier(identifierSet, repl.Source)
+		}
+	}
+}
+
+// addStrategicMergePatchTargetIdentifier extracts the GVKN a strategic-merge
+// patch targets from the patch's own content -- unlike Patches/
+// PatchesJson6902, a strategic-merge patch has no separate "target"
+// selector; it's matched against the GVKN it itself declares. patch is
+// either the patch content inline or a path to a file containing it; a
+// single-line value with no newline is treated as a path, matching how
+// collectPatchDocuments resolves the same field.
+func (doc *KustomizationDocument) addStrategicMergePatchTargetIdentifier(identifierSet set, patch string) {
+	content := []byte(patch)
+	if !strings.Contains(patch, "\n") && doc.Loader != nil {
+		if loaded, err := doc.readPatchFile(patch); err == nil {
+			content = loaded
+		}
+	}
+
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return
+	}
+	group, version := splitAPIVersion(stringField(m, "apiVersion"))
+	kind := stringField(m, "kind")
+	name := stringField(nestedMap(m, "metadata"), "name")
+
+	gvkn := gvknPath(group, version, kind, name)
+	if gvkn == "" {
+		return
+	}
+	identifierSet[fmt.Sprintf("patchTarget:%s", gvkn)] = struct{}{}
+}
+
+// readPatchFile resolves path the same way collectPatchDocuments' file
+// sources are resolved, then loads its content through doc.Loader.
+func (doc *KustomizationDocument) readPatchFile(path string) ([]byte, error) {
+	next, err := doc.Document.FromRelativePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Loader.ReadFile(next.FilePath)
+}
+
+// splitAPIVersion splits a "group/version" apiVersion into its group and
+// version, or returns ("", apiVersion) for a core-group apiVersion like "v1".
+func splitAPIVersion(apiVersion string) (group, version string) {
+	if idx := strings.Index(apiVersion, "/"); idx >= 0 {
+		return apiVersion[:idx], apiVersion[idx+1:]
+	}
+	return "", apiVersion
+}
+
+func nestedMap(m map[string]interface{}, key string) map[string]interface{} {
+	v, _ := m[key].(map[string]interface{})
+	return v
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func addPatchTargetIdentifier(identifierSet set, target *types.Selector) {
+	if target == nil {
+		return
+	}
+	gvkn := gvknPath(target.Group, target.Version, target.Kind, target.Name)
+	if gvkn == "" {
+		return
+	}
+	identifierSet[fmt.Sprintf("patchTarget:%s", gvkn)] = struct{}{}
+}
+
+func addReplacementSourceIdentifier(identifierSet set, source *types.SourceSelector) {
+	if source == nil || source.Kind == "" || source.Name == "" {
+		return
+	}
+	identifierSet[fmt.Sprintf("replacementSource:%s/%s", pluralizeKind(source.Kind), source.Name)] = struct{}{}
+}
+
+// gvknPath renders a group/version/kind/name selector as a single
+// "/"-joined string, omitting the group segment for core-group resources
+// (e.g. "v1/ConfigMap/my-config" rather than "/v1/ConfigMap/my-config").
+func gvknPath(group, version, kind, name string) string {
+	if kind == "" && name == "" {
+		return ""
+	}
+	parts := make([]string, 0, 4)
+	if group != "" {
+		parts = append(parts, group)
+	}
+	if version != "" {
+		parts = append(parts, version)
+	}
+	if kind != "" {
+		parts = append(parts, kind)
+	}
+	if name != "" {
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, "/")
+}
+
+// pluralizeKind naively pluralizes a Kind for use in a resource-type
+// identifier segment, e.g. "ConfigMap" -> "configmaps". It's a heuristic,
+// not a full English pluralizer, but it matches how Kubernetes resource
+// types are conventionally named.
+func pluralizeKind(kind string) string {
+	lower := strings.ToLower(kind)
+	if strings.HasSuffix(lower, "s") {
+		return lower + "es"
+	}
+	return lower + "s"
+}
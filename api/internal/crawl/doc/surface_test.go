@@ -0,0 +1,116 @@
+package doc
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestStripFileSourceKey(t *testing.T) {
+	cases := map[string]string{
+		"configs/app.env":         "configs/app.env",
+		"APP_ENV=configs/app.env": "configs/app.env",
+		"key=with=equals":         "with=equals",
+		"":                        "",
+	}
+	for in, want := range cases {
+		if got := stripFileSourceKey(in); got != want {
+			t.Errorf("stripFileSourceKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGvknPath(t *testing.T) {
+	cases := []struct {
+		group, version, kind, name string
+		want                       string
+	}{
+		{"apps", "v1", "Deployment", "my-app", "apps/v1/Deployment/my-app"},
+		{"", "v1", "ConfigMap", "my-config", "v1/ConfigMap/my-config"},
+		{"", "", "", "", ""},
+	}
+	for _, c := range cases {
+		if got := gvknPath(c.group, c.version, c.kind, c.name); got != c.want {
+			t.Errorf("gvknPath(%q, %q, %q, %q) = %q, want %q", c.group, c.version, c.kind, c.name, got, c.want)
+		}
+	}
+}
+
+func TestSplitAPIVersion(t *testing.T) {
+	cases := []struct {
+		apiVersion  string
+		wantGroup   string
+		wantVersion string
+	}{
+		{"apps/v1", "apps", "v1"},
+		{"v1", "", "v1"},
+		{"", "", ""},
+	}
+	for _, c := range cases {
+		group, version := splitAPIVersion(c.apiVersion)
+		if group != c.wantGroup || version != c.wantVersion {
+			t.Errorf("splitAPIVersion(%q) = (%q, %q), want (%q, %q)", c.apiVersion, group, version, c.wantGroup, c.wantVersion)
+		}
+	}
+}
+
+func TestPluralizeKind(t *testing.T) {
+	cases := map[string]string{
+		"ConfigMap": "configmaps",
+		"Ingress":   "ingresses",
+		"Pod":       "pods",
+	}
+	for kind, want := range cases {
+		if got := pluralizeKind(kind); got != want {
+			t.Errorf("pluralizeKind(%q) = %q, want %q", kind, got, want)
+		}
+	}
+}
+
+func TestAddPatchTargetIdentifier(t *testing.T) {
+	identifierSet := make(set)
+	addPatchTargetIdentifier(identifierSet, &types.Selector{
+		Gvk:  resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Name: "my-app",
+	})
+	if _, ok := identifierSet["patchTarget:apps/v1/Deployment/my-app"]; !ok {
+		t.Errorf("addPatchTargetIdentifier did not add expected identifier, got %v", identifierSet)
+	}
+}
+
+func TestAddReplacementSourceIdentifier(t *testing.T) {
+	identifierSet := make(set)
+	addReplacementSourceIdentifier(identifierSet, &types.SourceSelector{
+		ResId: resid.ResId{Gvk: resid.Gvk{Kind: "ConfigMap"}, Name: "my-config"},
+	})
+	if _, ok := identifierSet["replacementSource:configmaps/my-config"]; !ok {
+		t.Errorf("addReplacementSourceIdentifier did not add expected identifier, got %v", identifierSet)
+	}
+}
+
+func TestAddStrategicMergePatchTargetIdentifierInline(t *testing.T) {
+	doc := &KustomizationDocument{Document: Document{FilePath: "kustomization.yaml"}}
+	identifierSet := make(set)
+
+	inlinePatch := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: my-app\nspec:\n  replicas: 2\n"
+	doc.addStrategicMergePatchTargetIdentifier(identifierSet, inlinePatch)
+
+	if _, ok := identifierSet["patchTarget:apps/v1/Deployment/my-app"]; !ok {
+		t.Errorf("addStrategicMergePatchTargetIdentifier did not add expected identifier, got %v", identifierSet)
+	}
+}
+
+func TestAddStrategicMergePatchTargetIdentifierIgnoresUnparsable(t *testing.T) {
+	doc := &KustomizationDocument{Document: Document{FilePath: "kustomization.yaml"}}
+	identifierSet := make(set)
+
+	// A single-line value with no Loader configured can't be resolved as a
+	// file, so it's parsed as-is; since it isn't valid YAML content for a
+	// GVKN, nothing should be added.
+	doc.addStrategicMergePatchTargetIdentifier(identifierSet, "patches/deployment.yaml")
+
+	if len(identifierSet) != 0 {
+		t.Errorf("expected no identifiers for an unresolvable patch path, got %v", identifierSet)
+	}
+}